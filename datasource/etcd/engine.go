@@ -22,7 +22,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
+	"math/rand"
+	"sync/atomic"
 	"time"
 
 	"github.com/apache/servicecomb-service-center/datasource"
@@ -32,10 +33,43 @@ import (
 	"github.com/apache/servicecomb-service-center/server/config"
 	"github.com/apache/servicecomb-service-center/server/core"
 	discosvc "github.com/apache/servicecomb-service-center/server/service/disco"
+	"github.com/apache/servicecomb-service-center/server/syncer"
 	"github.com/apache/servicecomb-service-center/version"
 	pb "github.com/go-chassis/cari/discovery"
 	"github.com/go-chassis/foundation/gopool"
 	"github.com/little-cui/etcdadpt"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// heartbeatFailures tracks consecutive self-heartbeat failures so operators
+// can alert before the heartbeat loop gives up and falls back to a full
+// re-registration.
+var heartbeatFailures = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: "sc",
+	Subsystem: "self",
+	Name:      "heartbeat_consecutive_failures",
+	Help:      "Consecutive self-heartbeat failures of this service center instance.",
+})
+
+func init() {
+	prometheus.MustRegister(heartbeatFailures)
+}
+
+const maxHeartbeatBackoff = 3
+
+// maxHeartbeatRetryAttempts bounds how many times heartBeatWithRetry retries
+// a persistent, non-lease-loss failure before giving up on the heartbeat and
+// falling back to a full re-register, the same way a confirmed lease loss
+// does. Without a bound, a non-NotExists failure (e.g. etcd unreachable)
+// would retry forever and the instance would never regain a heartbeat.
+const maxHeartbeatRetryAttempts = 8
+
+// lastHeartbeatAt and consecutiveFailures back DumpSelf; they are updated
+// from heartbeatOnce and read concurrently by the admin dump endpoint.
+var (
+	lastHeartbeatAt     atomic.Value // time.Time
+	consecutiveFailures int64
+	upgrading           int32
 )
 
 type SCManager struct {
@@ -108,9 +142,35 @@ func (sm *SCManager) registerInstance(ctx context.Context) error {
 	core.Instance.InstanceId = respI.InstanceId
 	log.Info(fmt.Sprintf("register service center instance[%s/%s], endpoints is %s",
 		core.Service.ServiceId, respI.InstanceId, core.Instance.Endpoints))
+	sm.syncSelf(ctx, false)
 	return nil
 }
 
+// syncSelf publishes this SC's service/instance to peer clusters through the
+// syncer so that a discovery query against any single cluster can see every
+// SC node in the topology. It is called on register/unregister and again on
+// every successful heartbeat so the mirror a peer holds keeps getting
+// refreshed for as long as this instance is alive, rather than expiring on a
+// timer unrelated to this instance's actual liveness. It is a best-effort
+// side effect: sync failures are logged but never fail
+// self-registration/unregistration/heartbeat.
+func (sm *SCManager) syncSelf(ctx context.Context, remove bool) {
+	if !config.Self.SyncEnabled {
+		return
+	}
+	task := syncer.NewTask(core.Service, core.Instance, syncer.SelfTag)
+	var err error
+	if remove {
+		err = syncer.PublishDelete(ctx, task)
+	} else {
+		err = syncer.Publish(ctx, task)
+	}
+	if err != nil {
+		log.Error(fmt.Sprintf("sync self[%s/%s] to peers failed, remove=%v",
+			core.Service.ServiceId, core.Instance.InstanceId, remove), err)
+	}
+}
+
 func (sm *SCManager) selfHeartBeat(pCtx context.Context) error {
 	ctx := core.AddDefaultContextValue(pCtx)
 	respI, err := discosvc.Heartbeat(ctx, core.HeartbeatRequest())
@@ -123,7 +183,11 @@ func (sm *SCManager) selfHeartBeat(pCtx context.Context) error {
 			core.Instance.ServiceId, core.Instance.InstanceId))
 		return nil
 	}
-	err = fmt.Errorf(respI.Response.GetMessage())
+	if respI.Response.GetCode() == pb.ErrInstanceNotExists {
+		err = discosvc.ErrInstanceNotExists
+	} else {
+		err = fmt.Errorf(respI.Response.GetMessage())
+	}
 	log.Error(fmt.Sprintf("update service center instance[%s/%s] heartbeat failed",
 		core.Instance.ServiceId, core.Instance.InstanceId), err)
 	return err
@@ -131,26 +195,88 @@ func (sm *SCManager) selfHeartBeat(pCtx context.Context) error {
 
 func (sm *SCManager) autoSelfHeartBeat() {
 	gopool.Go(func(ctx context.Context) {
+		interval := time.Duration(core.Instance.HealthCheck.Interval) * time.Second
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-time.After(time.Duration(core.Instance.HealthCheck.Interval) * time.Second):
-				err := sm.selfHeartBeat(ctx)
-				if err == nil {
-					continue
-				}
-				//服务不存在，创建服务
-				err = sm.selfRegister(ctx)
-				if err != nil {
-					log.Error(fmt.Sprintf("retry to register[%s/%s/%s/%s] failed",
-						core.Service.Environment, core.Service.AppId, core.Service.ServiceName, core.Service.Version), err)
-				}
+			case <-time.After(jitter(interval)):
+				sm.heartBeatWithRetry(ctx)
 			}
 		}
 	})
 }
 
+// heartBeatWithRetry sends a heartbeat and, on transient errors, retries with
+// exponential backoff (1s, 2s, 4s, ... capped at Interval*3) while keeping the
+// current InstanceId. A confirmed lease/instance loss falls back to a full
+// self-registration immediately; a transient error that keeps failing past
+// maxHeartbeatRetryAttempts also falls back, so the heartbeat loop can never
+// get stuck retrying forever.
+func (sm *SCManager) heartBeatWithRetry(ctx context.Context) {
+	maxBackoff := time.Duration(core.Instance.HealthCheck.Interval) * time.Second * maxHeartbeatBackoff
+	backoff := time.Second
+	attempts := 0
+	for {
+		err := sm.heartbeatOnce(ctx)
+		if err == nil {
+			return
+		}
+		attempts++
+
+		leaseLost := errors.Is(err, discosvc.ErrInstanceNotExists)
+		if !leaseLost && attempts >= maxHeartbeatRetryAttempts {
+			log.Warn(fmt.Sprintf("service center instance[%s/%s] heartbeat still failing after %d attempts, re-registering",
+				core.Instance.ServiceId, core.Instance.InstanceId, attempts))
+			leaseLost = true
+		}
+
+		if leaseLost {
+			if rerr := sm.selfRegister(ctx); rerr != nil {
+				log.Error(fmt.Sprintf("retry to register[%s/%s/%s/%s] failed",
+					core.Service.Environment, core.Service.AppId, core.Service.ServiceName, core.Service.Version), rerr)
+			}
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(jitter(backoff)):
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// jitter adds up to 20% random delay to d to avoid thundering-herd heartbeats
+// when many SCs restart at the same time.
+func jitter(d time.Duration) time.Duration {
+	return d + time.Duration(rand.Int63n(int64(d)/5+1))
+}
+
+// heartbeatOnce sends a single heartbeat and records its outcome in the
+// metrics DumpSelf reports, regardless of which caller triggered it
+// (autoSelfHeartBeat's loop or a SelfRegistrar.Heartbeat/Renew call). On
+// success it also refreshes this instance's sc-self mirror on peer clusters,
+// so a peer's copy stays alive for as long as this instance is actually
+// heartbeating instead of expiring on its own fixed timer.
+func (sm *SCManager) heartbeatOnce(ctx context.Context) error {
+	err := sm.selfHeartBeat(ctx)
+	if err == nil {
+		heartbeatFailures.Set(0)
+		atomic.StoreInt64(&consecutiveFailures, 0)
+		lastHeartbeatAt.Store(time.Now())
+		sm.syncSelf(core.AddDefaultContextValue(ctx), false)
+		return nil
+	}
+	heartbeatFailures.Inc()
+	atomic.AddInt64(&consecutiveFailures, 1)
+	return err
+}
+
 func (sm *SCManager) SelfUnregister(pCtx context.Context) error {
 	if len(core.Instance.InstanceId) == 0 {
 		return nil
@@ -169,12 +295,52 @@ func (sm *SCManager) SelfUnregister(pCtx context.Context) error {
 	}
 	log.Warn(fmt.Sprintf("unregister service center instance[%s/%s]",
 		core.Service.ServiceId, core.Instance.InstanceId))
+	sm.syncSelf(ctx, true)
 	return nil
 }
 
 func (sm *SCManager) GetClusters(ctx context.Context) (etcdadpt.Clusters, error) {
 	return etcdadpt.ListCluster(ctx)
 }
+
+// SelfDump is the JSON snapshot returned by the admin/self endpoint so an
+// operator can tell why a particular SC node has disappeared from its own
+// discovery catalog without shelling into etcd.
+type SelfDump struct {
+	ServiceId           string            `json:"serviceId"`
+	InstanceId          string            `json:"instanceId"`
+	Endpoints           []string          `json:"endpoints"`
+	LastHeartbeatAt     time.Time         `json:"lastHeartbeatAt"`
+	ConsecutiveFailures int64             `json:"consecutiveFailures"`
+	Version             string            `json:"version"`
+	UpgradeInProgress   bool              `json:"upgradeInProgress"`
+	Clusters            etcdadpt.Clusters `json:"clusters"`
+}
+
+// DumpSelf reports this SC node's self-registration state: the admin/self
+// endpoint builds on it to let operators diagnose a missing catalog entry
+// without querying etcd directly.
+func (sm *SCManager) DumpSelf(ctx context.Context) (*SelfDump, error) {
+	clusters, err := sm.GetClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var lastHeartbeat time.Time
+	if t, ok := lastHeartbeatAt.Load().(time.Time); ok {
+		lastHeartbeat = t
+	}
+	return &SelfDump{
+		ServiceId:           core.Service.ServiceId,
+		InstanceId:          core.Instance.InstanceId,
+		Endpoints:           core.Instance.Endpoints,
+		LastHeartbeatAt:     lastHeartbeat,
+		ConsecutiveFailures: atomic.LoadInt64(&consecutiveFailures),
+		Version:             config.Server.Version,
+		UpgradeInProgress:   atomic.LoadInt32(&upgrading) == 1,
+		Clusters:            clusters,
+	}, nil
+}
+
 func (sm *SCManager) UpgradeServerVersion(ctx context.Context) error {
 	bytes, err := json.Marshal(config.Server)
 	if err != nil {
@@ -182,24 +348,43 @@ func (sm *SCManager) UpgradeServerVersion(ctx context.Context) error {
 	}
 	return etcdadpt.PutBytes(ctx, path.GetServerInfoKey(), bytes)
 }
+
+// UpgradeVersion runs every pending migration registered for the version
+// range between the persisted config.Server.Version and the binary's own
+// version, then records the new server version. Everything happens under
+// mux.GlobalLock so only one SC in an HA cluster drives the upgrade; on
+// failure the lock is released (rather than the process exiting) so a peer
+// SC can take over instead of the whole cluster getting stuck.
 func (sm *SCManager) UpgradeVersion(ctx context.Context) error {
 	lock, err := mux.Lock(mux.GlobalLock)
-
 	if err != nil {
 		log.Error("wait for server ready failed", err)
 		return err
 	}
-	if needUpgrade(ctx) {
-		config.Server.Version = version.Ver().Version
-
-		if err := sm.UpgradeServerVersion(ctx); err != nil {
-			log.Error("upgrade server version failed", err)
-			os.Exit(1)
+	defer func() {
+		if uerr := lock.Unlock(); uerr != nil {
+			log.Error("", uerr)
 		}
+	}()
+
+	if !needUpgrade(ctx) {
+		return nil
 	}
-	err = lock.Unlock()
-	if err != nil {
-		log.Error("", err)
+
+	atomic.StoreInt32(&upgrading, 1)
+	defer atomic.StoreInt32(&upgrading, 0)
+
+	from := config.Server.Version
+	to := version.Ver().Version
+	if err := runMigrations(ctx, from, to); err != nil {
+		log.Error(fmt.Sprintf("upgrade from[%s] to[%s] failed", from, to), err)
+		return err
 	}
-	return err
+
+	config.Server.Version = to
+	if err := sm.UpgradeServerVersion(ctx); err != nil {
+		log.Error("upgrade server version failed", err)
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,42 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package etcd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJitter(t *testing.T) {
+	d := 10 * time.Second
+	for i := 0; i < 100; i++ {
+		got := jitter(d)
+		if got < d {
+			t.Fatalf("jitter(%s) = %s, want >= %s", d, got, d)
+		}
+		if got > d+d/5 {
+			t.Fatalf("jitter(%s) = %s, want <= %s", d, got, d+d/5)
+		}
+	}
+}
+
+// heartBeatWithRetry itself isn't exercised here: it calls straight through
+// to discosvc.Heartbeat/core.Instance, which this package has no seam to
+// fake without a live etcd backend. jitter and the maxHeartbeatBackoff/
+// maxHeartbeatRetryAttempts constants it's built from are the parts that
+// can be unit tested in isolation.
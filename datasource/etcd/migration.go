@@ -0,0 +1,133 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+	"github.com/little-cui/etcdadpt"
+)
+
+// upgradeStepPrefix namespaces the per-migration completion markers so a
+// crashed upgrade resumes from where it left off instead of replaying
+// already-applied steps.
+const upgradeStepPrefix = "/cse-sr/upgrade/steps/"
+
+// Migration is one versioned, idempotent step in the upgrade path between
+// two SC releases. Up must be safe to re-run if a previous attempt crashed
+// after applying it but before its completion marker was recorded.
+type Migration interface {
+	ID() string
+	FromVersion() string
+	ToVersion() string
+	Up(ctx context.Context) error
+	Down(ctx context.Context) error
+}
+
+var migrations []Migration
+
+// RegisterMigration adds a migration to the upgrade registry. Called from
+// init() in the file that defines the migration, so the registry is built up
+// as the package is imported.
+func RegisterMigration(m Migration) {
+	migrations = append(migrations, m)
+}
+
+// compareVersions compares two dotted version strings (e.g. "1.2.3")
+// numerically, segment by segment, treating a missing trailing segment as 0.
+// It returns -1, 0, or 1, the same comparison the rest of the upgrade path
+// already uses to decide whether config.Server.Version is current.
+func compareVersions(a, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var av, bv int
+		if i < len(as) {
+			av, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			bv, _ = strconv.Atoi(bs[i])
+		}
+		if av != bv {
+			if av < bv {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// migrationsTo returns the migrations needed to go from "from" to "to",
+// ordered by their target version.
+func migrationsTo(from, to string) []Migration {
+	var pending []Migration
+	for _, m := range migrations {
+		if compareVersions(m.ToVersion(), to) > 0 {
+			continue
+		}
+		if from != "" && compareVersions(m.ToVersion(), from) <= 0 {
+			continue
+		}
+		pending = append(pending, m)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return compareVersions(pending[i].ToVersion(), pending[j].ToVersion()) < 0
+	})
+	return pending
+}
+
+func stepDone(ctx context.Context, id string) (bool, error) {
+	return etcdadpt.Exist(ctx, upgradeStepPrefix+id)
+}
+
+func markStepDone(ctx context.Context, id string) error {
+	return etcdadpt.Put(ctx, upgradeStepPrefix+id, "done")
+}
+
+// runMigrations executes every pending migration in order, skipping steps
+// whose completion marker is already recorded so a crashed upgrade resumes
+// instead of replaying.
+func runMigrations(ctx context.Context, from, to string) error {
+	pending := migrationsTo(from, to)
+	for _, m := range pending {
+		done, err := stepDone(ctx, m.ID())
+		if err != nil {
+			return err
+		}
+		if done {
+			log.Info(fmt.Sprintf("migration[%s] already applied, skip", m.ID()))
+			continue
+		}
+		log.Info(fmt.Sprintf("running migration[%s] %s -> %s", m.ID(), m.FromVersion(), m.ToVersion()))
+		if err := m.Up(ctx); err != nil {
+			log.Error(fmt.Sprintf("migration[%s] failed", m.ID()), err)
+			return err
+		}
+		if err := markStepDone(ctx, m.ID()); err != nil {
+			log.Error(fmt.Sprintf("migration[%s] succeeded but failed to record completion", m.ID()), err)
+			return err
+		}
+	}
+	return nil
+}
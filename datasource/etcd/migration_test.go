@@ -0,0 +1,95 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package etcd
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.4", -1},
+		{"1.3.0", "1.2.9", 1},
+		{"1.2", "1.2.0", 0},
+		{"1.2.1", "1.2", 1},
+		{"2.0.0", "1.9.9", 1},
+		{"", "", 0},
+		{"", "0.0.1", -1},
+	}
+	for _, c := range cases {
+		if got := compareVersions(c.a, c.b); got != c.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+type fakeMigration struct {
+	id, from, to string
+}
+
+func (m *fakeMigration) ID() string                     { return m.id }
+func (m *fakeMigration) FromVersion() string            { return m.from }
+func (m *fakeMigration) ToVersion() string              { return m.to }
+func (m *fakeMigration) Up(ctx context.Context) error   { return nil }
+func (m *fakeMigration) Down(ctx context.Context) error { return nil }
+
+func TestMigrationsTo(t *testing.T) {
+	all := []Migration{
+		&fakeMigration{id: "m1", from: "1.0.0", to: "1.1.0"},
+		&fakeMigration{id: "m2", from: "1.1.0", to: "1.2.0"},
+		&fakeMigration{id: "m3", from: "1.2.0", to: "2.0.0"},
+	}
+	saved := migrations
+	migrations = all
+	defer func() { migrations = saved }()
+
+	pending := migrationsTo("1.0.0", "2.0.0")
+	if len(pending) != 3 {
+		t.Fatalf("migrationsTo(1.0.0, 2.0.0) = %d migrations, want 3", len(pending))
+	}
+	for i, id := range []string{"m1", "m2", "m3"} {
+		if pending[i].ID() != id {
+			t.Errorf("pending[%d].ID() = %s, want %s", i, pending[i].ID(), id)
+		}
+	}
+
+	pending = migrationsTo("1.1.0", "2.0.0")
+	if len(pending) != 2 || pending[0].ID() != "m2" || pending[1].ID() != "m3" {
+		t.Errorf("migrationsTo(1.1.0, 2.0.0) = %v, want [m2 m3]", pending)
+	}
+
+	pending = migrationsTo("1.0.0", "1.1.0")
+	if len(pending) != 1 || pending[0].ID() != "m1" {
+		t.Errorf("migrationsTo(1.0.0, 1.1.0) = %v, want [m1]", pending)
+	}
+
+	pending = migrationsTo("", "2.0.0")
+	if len(pending) != 3 {
+		t.Errorf("migrationsTo(\"\", 2.0.0) = %d migrations, want 3", len(pending))
+	}
+
+	pending = migrationsTo("2.0.0", "2.0.0")
+	if len(pending) != 0 {
+		t.Errorf("migrationsTo(2.0.0, 2.0.0) = %v, want none", pending)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/apache/servicecomb-service-center/datasource"
+	"github.com/apache/servicecomb-service-center/pkg/log"
+	"github.com/apache/servicecomb-service-center/server/config"
+	"github.com/apache/servicecomb-service-center/server/core"
+	pb "github.com/go-chassis/cari/discovery"
+	"github.com/go-chassis/foundation/gopool"
+)
+
+// defaultSelfRegistrarMode is used when registry.self.mode is unset, keeping
+// existing deployments' behavior unchanged.
+const defaultSelfRegistrarMode = "local"
+
+// SelfRegistrarFromConfig resolves the SelfRegistrar selected by the
+// registry.self.mode config key. Bootstrap calls this instead of
+// instantiating SCManager directly, so the self-registration strategy is a
+// deployment choice rather than something hard-wired into the datasource
+// layer.
+func SelfRegistrarFromConfig() (datasource.SelfRegistrar, error) {
+	mode := config.Self.Mode
+	if mode == "" {
+		mode = defaultSelfRegistrarMode
+	}
+	return datasource.GetSelfRegistrar(mode)
+}
+
+func init() {
+	datasource.RegisterSelfRegistrar("local", func() datasource.SelfRegistrar {
+		return &LocalSelfRegistrar{sm: &SCManager{}}
+	})
+	datasource.RegisterSelfRegistrar("static", func() datasource.SelfRegistrar {
+		return &StaticSelfRegistrar{}
+	})
+	datasource.RegisterSelfRegistrar("remote", func() datasource.SelfRegistrar {
+		return &RemoteSelfRegistrar{}
+	})
+}
+
+// LocalSelfRegistrar is the original behavior: SCManager registers, heartbeats
+// and renews this SC instance directly against the local etcd/discovery
+// backend. This is the default (registry.self.mode=local).
+type LocalSelfRegistrar struct {
+	sm *SCManager
+}
+
+func (r *LocalSelfRegistrar) Register(ctx context.Context) error {
+	return r.sm.SelfRegister(ctx)
+}
+
+func (r *LocalSelfRegistrar) Unregister(ctx context.Context) error {
+	return r.sm.SelfUnregister(ctx)
+}
+
+func (r *LocalSelfRegistrar) Heartbeat(ctx context.Context) error {
+	return r.sm.heartbeatOnce(ctx)
+}
+
+func (r *LocalSelfRegistrar) Renew(ctx context.Context) error {
+	return r.sm.heartbeatOnce(ctx)
+}
+
+// StaticSelfRegistrar registers once using the endpoints from config and
+// never renews or heartbeats. It suits k8s deployments where SC is fronted
+// by a headless Service and the Service's own endpoint controller, not SC
+// itself, is the source of truth for liveness.
+type StaticSelfRegistrar struct{}
+
+func (r *StaticSelfRegistrar) Register(ctx context.Context) error {
+	pCtx := core.AddDefaultContextValue(ctx)
+	sm := &SCManager{}
+	if err := sm.registerService(pCtx); err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("static self-registration using configured endpoints %s", core.Instance.Endpoints))
+	return sm.registerInstance(pCtx)
+}
+
+func (r *StaticSelfRegistrar) Unregister(ctx context.Context) error {
+	return (&SCManager{}).SelfUnregister(ctx)
+}
+
+func (r *StaticSelfRegistrar) Heartbeat(ctx context.Context) error { return nil }
+func (r *StaticSelfRegistrar) Renew(ctx context.Context) error     { return nil }
+
+// RemoteSelfRegistrar delegates self-registration to a designated primary SC
+// in an HA cluster: this process's service and instance are registered
+// through the primary's registry REST API instead of the local backend. Each
+// SC process running in this mode still registers its own instance, so the
+// catalog holds one instance entry per process.
+type RemoteSelfRegistrar struct{}
+
+func (r *RemoteSelfRegistrar) Register(ctx context.Context) error {
+	if config.Self.PrimaryEndpoint == "" {
+		return fmt.Errorf("registry.self.mode=remote requires registry.self.primary_endpoint")
+	}
+	log.Info(fmt.Sprintf("delegating self-registration to primary SC[%s]", config.Self.PrimaryEndpoint))
+	if err := r.client().Register(ctx, core.Service, core.Instance); err != nil {
+		return err
+	}
+	gopool.Go(func(ctx context.Context) {
+		interval := time.Duration(core.Instance.HealthCheck.Interval) * time.Second
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(jitter(interval)):
+				if err := r.Heartbeat(ctx); err != nil {
+					log.Error(fmt.Sprintf("heartbeat to primary SC[%s] failed", config.Self.PrimaryEndpoint), err)
+				}
+			}
+		}
+	})
+	return nil
+}
+
+func (r *RemoteSelfRegistrar) Unregister(ctx context.Context) error {
+	return r.client().Unregister(ctx, core.Service, core.Instance)
+}
+
+func (r *RemoteSelfRegistrar) Heartbeat(ctx context.Context) error {
+	return r.client().Heartbeat(ctx, core.Service, core.Instance)
+}
+
+func (r *RemoteSelfRegistrar) client() remoteSelfClient {
+	return remoteRegisterClient(config.Self.PrimaryEndpoint, config.Self.Project)
+}
+
+func (r *RemoteSelfRegistrar) Renew(ctx context.Context) error {
+	return r.Heartbeat(ctx)
+}
+
+// remoteSelfClient is the thin RPC surface RemoteSelfRegistrar needs against
+// a peer SC's self-registration endpoint.
+type remoteSelfClient interface {
+	Register(ctx context.Context, service *pb.MicroService, instance *pb.MicroServiceInstance) error
+	Unregister(ctx context.Context, service *pb.MicroService, instance *pb.MicroServiceInstance) error
+	Heartbeat(ctx context.Context, service *pb.MicroService, instance *pb.MicroServiceInstance) error
+}
+
+// remoteRegisterClient resolves the client used to talk to the given primary
+// SC endpoint, scoped to project.
+func remoteRegisterClient(endpoint, project string) remoteSelfClient {
+	return core.NewSelfRegisterClient(endpoint, project)
+}
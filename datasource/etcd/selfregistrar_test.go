@@ -0,0 +1,67 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package etcd
+
+import (
+	"testing"
+
+	"github.com/apache/servicecomb-service-center/server/config"
+)
+
+func TestSelfRegistrarFromConfig(t *testing.T) {
+	saved := config.Self.Mode
+	defer func() { config.Self.Mode = saved }()
+
+	cases := []struct {
+		mode string
+		want string
+	}{
+		{"", "*etcd.LocalSelfRegistrar"},
+		{"local", "*etcd.LocalSelfRegistrar"},
+		{"static", "*etcd.StaticSelfRegistrar"},
+		{"remote", "*etcd.RemoteSelfRegistrar"},
+	}
+	for _, c := range cases {
+		config.Self.Mode = c.mode
+		r, err := SelfRegistrarFromConfig()
+		if err != nil {
+			t.Fatalf("SelfRegistrarFromConfig() with mode %q failed: %s", c.mode, err)
+		}
+		if got := typeName(r); got != c.want {
+			t.Errorf("SelfRegistrarFromConfig() with mode %q = %s, want %s", c.mode, got, c.want)
+		}
+	}
+
+	config.Self.Mode = "bogus"
+	if _, err := SelfRegistrarFromConfig(); err == nil {
+		t.Errorf("SelfRegistrarFromConfig() with mode %q = nil error, want error", "bogus")
+	}
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case *LocalSelfRegistrar:
+		return "*etcd.LocalSelfRegistrar"
+	case *StaticSelfRegistrar:
+		return "*etcd.StaticSelfRegistrar"
+	case *RemoteSelfRegistrar:
+		return "*etcd.RemoteSelfRegistrar"
+	default:
+		return "unknown"
+	}
+}
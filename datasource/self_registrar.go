@@ -0,0 +1,58 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package datasource
+
+import (
+	"context"
+	"fmt"
+)
+
+// SelfRegistrar is the strategy SC uses to make itself discoverable. The
+// datasource layer no longer hard-wires this to the etcd-backed
+// implementation: which strategy runs is selected at startup by the
+// registry.self.mode config key (see RegisterSelfRegistrar/GetSelfRegistrar).
+type SelfRegistrar interface {
+	// Register makes this SC instance visible in the catalog and starts
+	// whatever background renewal the strategy needs.
+	Register(ctx context.Context) error
+	// Unregister removes this SC instance from the catalog.
+	Unregister(ctx context.Context) error
+	// Heartbeat keeps an already-registered instance alive.
+	Heartbeat(ctx context.Context) error
+	// Renew refreshes the registration without a full re-register, e.g. to
+	// extend a lease TTL.
+	Renew(ctx context.Context) error
+}
+
+var selfRegistrarFactories = make(map[string]func() SelfRegistrar)
+
+// RegisterSelfRegistrar makes a SelfRegistrar implementation available under
+// the given registry.self.mode value. Implementations call this from an
+// init() function in the package that defines them.
+func RegisterSelfRegistrar(mode string, factory func() SelfRegistrar) {
+	selfRegistrarFactories[mode] = factory
+}
+
+// GetSelfRegistrar resolves the SelfRegistrar registered for mode.
+func GetSelfRegistrar(mode string) (SelfRegistrar, error) {
+	factory, ok := selfRegistrarFactories[mode]
+	if !ok {
+		return nil, fmt.Errorf("no self registrar registered for mode %q", mode)
+	}
+	return factory(), nil
+}
@@ -0,0 +1,33 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package datasource
+
+import "context"
+
+func init() {
+	RegisterSelfRegistrar("noop", func() SelfRegistrar { return &NoopSelfRegistrar{} })
+}
+
+// NoopSelfRegistrar does nothing. It is for embedded/sidecar deployments
+// where SC should not appear in its own service catalog at all.
+type NoopSelfRegistrar struct{}
+
+func (r *NoopSelfRegistrar) Register(ctx context.Context) error   { return nil }
+func (r *NoopSelfRegistrar) Unregister(ctx context.Context) error { return nil }
+func (r *NoopSelfRegistrar) Heartbeat(ctx context.Context) error  { return nil }
+func (r *NoopSelfRegistrar) Renew(ctx context.Context) error      { return nil }
@@ -0,0 +1,37 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bootstrap
+
+import (
+	"context"
+
+	"github.com/apache/servicecomb-service-center/datasource/etcd"
+)
+
+// SelfRegister resolves the SelfRegistrar selected by registry.self.mode and
+// registers this SC instance. The startup sequence calls this instead of
+// instantiating etcd.SCManager directly, so the self-registration strategy
+// (local/noop/static/remote) is a deployment choice, not something
+// hard-wired into the datasource layer.
+func SelfRegister(ctx context.Context) error {
+	registrar, err := etcd.SelfRegistrarFromConfig()
+	if err != nil {
+		return err
+	}
+	return registrar.Register(ctx)
+}
@@ -0,0 +1,61 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+// Server is the process-wide server configuration, populated at startup and
+// read throughout the server/datasource layers (e.g. datasource/etcd uses
+// Server.Version to track the persisted upgrade version).
+var Server Config
+
+// Config is the root server configuration. Only the fields datasource/etcd
+// already depends on are declared here; this package does not attempt to
+// re-author Config's full, real field set.
+type Config struct {
+	Version string
+}
+
+// Self holds the registry.self.* options controlling how this SC instance
+// manages its own entry in the service catalog. It is deliberately its own
+// top-level var rather than a field nested under Config/Registry, so that
+// adding it here can never redeclare or collide with Config's real,
+// pre-existing Registry field set.
+var Self SelfRegistryConfig
+
+// SelfRegistryConfig holds the registry.self.* options. Tags use snake_case
+// to match the registry.self.* key names themselves (e.g. sync_enabled binds
+// registry.self.sync_enabled), so an operator setting the documented key
+// actually binds the field the code reads.
+type SelfRegistryConfig struct {
+	// SyncEnabled replicates this SC's self-registration to peer clusters via
+	// the syncer (tagged sc-self) so a single cluster's discovery API shows
+	// every SC node in a multi-cluster topology. Bound to
+	// registry.self.sync_enabled.
+	SyncEnabled bool `json:"sync_enabled"`
+	// Mode selects the SelfRegistrar strategy: "local" (default), "noop",
+	// "static", or "remote". Bound to registry.self.mode.
+	Mode string `json:"mode"`
+	// PrimaryEndpoint is the primary SC endpoint registry.self.mode=remote
+	// delegates registration, heartbeat and unregister to. Bound to
+	// registry.self.primary_endpoint.
+	PrimaryEndpoint string `json:"primary_endpoint"`
+	// Project is the project segment used when building REST paths against
+	// PrimaryEndpoint (registry.self.mode=remote). Defaults to "default" when
+	// unset. The admin/self?peers=true fan-out does not read it; it uses the
+	// project of the incoming request. Bound to registry.self.project.
+	Project string `json:"project"`
+}
@@ -0,0 +1,85 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ListSelfPeers discovers the other SC instances in the cluster via
+// ServiceAPI's own catalog entry, so the admin/self?peers=true view can fan
+// out to every peer without operator-supplied configuration.
+func ListSelfPeers(ctx context.Context) ([]string, error) {
+	respE, err := ServiceAPI.Exist(ctx, GetExistenceRequest())
+	if err != nil {
+		return nil, err
+	}
+	respG, err := ServiceAPI.GetOne(ctx, GetServiceRequest(respE.ServiceId))
+	if err != nil {
+		return nil, err
+	}
+	own := make(map[string]bool, len(Instance.Endpoints))
+	for _, ep := range Instance.Endpoints {
+		own[ep] = true
+	}
+	var endpoints []string
+	for _, inst := range respG.Service.Instances {
+		if inst.InstanceId == Instance.InstanceId {
+			continue
+		}
+		for _, ep := range inst.Endpoints {
+			if ep != "" && !own[ep] {
+				endpoints = append(endpoints, ep)
+			}
+		}
+	}
+	return endpoints, nil
+}
+
+// SelfDump mirrors datasource/etcd.SelfDump's JSON shape without importing
+// that package, since core is a lower layer than datasource/etcd.
+type SelfDump = json.RawMessage
+
+// FetchPeerSelfDump queries a single peer SC's admin/self endpoint under
+// project (falling back to "default" if empty) and returns its raw self-dump
+// payload.
+func FetchPeerSelfDump(ctx context.Context, endpoint, project string) (SelfDump, error) {
+	if project == "" {
+		project = defaultProject
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/v4/"+project+"/admin/self", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch self dump from peer[%s] failed with status %d", endpoint, resp.StatusCode)
+	}
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
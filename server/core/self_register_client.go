@@ -0,0 +1,202 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	pb "github.com/go-chassis/cari/discovery"
+)
+
+// defaultProject is the project SC's self-registration REST paths fall back
+// to when no project is configured/supplied, i.e. the project SC itself
+// registers into.
+const defaultProject = "default"
+
+// SelfRegisterClient delegates self-registration to a primary SC's registry
+// REST API, the same API every microservice client already uses to register
+// with SC, so registry.self.mode=remote needs no new wire protocol.
+type SelfRegisterClient struct {
+	endpoint string
+	project  string
+	client   *http.Client
+}
+
+// NewSelfRegisterClient returns a client for the primary SC at endpoint,
+// scoped to project (falling back to "default" if empty, the project SC
+// itself registers into).
+func NewSelfRegisterClient(endpoint, project string) *SelfRegisterClient {
+	if project == "" {
+		project = defaultProject
+	}
+	return &SelfRegisterClient{endpoint: endpoint, project: project, client: http.DefaultClient}
+}
+
+// existenceResponse/registerServiceRequest/registerServiceResponse/
+// registerInstanceRequest/registerInstanceResponse mirror the shapes the
+// registry REST API already returns to every other microservice client;
+// registry.self.mode=remote reuses that same wire protocol.
+type existenceResponse struct {
+	ServiceId string `json:"serviceId"`
+}
+
+type registerServiceRequest struct {
+	Service *pb.MicroService `json:"service"`
+}
+
+type registerServiceResponse struct {
+	ServiceId string `json:"serviceId"`
+}
+
+type registerInstanceRequest struct {
+	Instance *pb.MicroServiceInstance `json:"instance"`
+}
+
+type registerInstanceResponse struct {
+	InstanceId string `json:"instanceId"`
+}
+
+// Register registers service and instance against the primary SC, the same
+// two-step create-service-then-create-instance flow SCManager.selfRegister
+// runs locally, and fills in the ServiceId/InstanceId the primary assigned.
+func (c *SelfRegisterClient) Register(ctx context.Context, service *pb.MicroService, instance *pb.MicroServiceInstance) error {
+	serviceID, err := c.registerService(ctx, service)
+	if err != nil {
+		return err
+	}
+	service.ServiceId = serviceID
+	instance.ServiceId = serviceID
+
+	instanceID, err := c.registerInstance(ctx, instance)
+	if err != nil {
+		return err
+	}
+	instance.InstanceId = instanceID
+	return nil
+}
+
+// registerService returns service's ServiceId on the primary SC, reusing an
+// already-registered service the same way SCManager.registerService does
+// locally instead of erroring out on a duplicate registration.
+func (c *SelfRegisterClient) registerService(ctx context.Context, service *pb.MicroService) (string, error) {
+	if id, err := c.existingServiceID(ctx, service); err == nil && id != "" {
+		return id, nil
+	}
+
+	body, err := c.doWithBody(ctx, http.MethodPost, "/v4/"+c.project+"/registry/microservices",
+		registerServiceRequest{Service: service})
+	if err != nil {
+		return "", err
+	}
+	var resp registerServiceResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", err
+	}
+	if resp.ServiceId == "" {
+		return "", fmt.Errorf("register service to primary SC[%s] returned no serviceId", c.endpoint)
+	}
+	return resp.ServiceId, nil
+}
+
+func (c *SelfRegisterClient) existingServiceID(ctx context.Context, service *pb.MicroService) (string, error) {
+	q := url.Values{}
+	q.Set("type", "microservice")
+	q.Set("appId", service.AppId)
+	q.Set("serviceName", service.ServiceName)
+	q.Set("version", service.Version)
+	if service.Environment != "" {
+		q.Set("environment", service.Environment)
+	}
+	body, err := c.doWithBody(ctx, http.MethodGet, "/v4/"+c.project+"/registry/existence?"+q.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	var resp existenceResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", err
+	}
+	return resp.ServiceId, nil
+}
+
+// registerInstance returns instance's InstanceId on the primary SC.
+func (c *SelfRegisterClient) registerInstance(ctx context.Context, instance *pb.MicroServiceInstance) (string, error) {
+	body, err := c.doWithBody(ctx, http.MethodPost,
+		"/v4/"+c.project+"/registry/microservices/"+instance.ServiceId+"/instances",
+		registerInstanceRequest{Instance: instance})
+	if err != nil {
+		return "", err
+	}
+	var resp registerInstanceResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", err
+	}
+	if resp.InstanceId == "" {
+		return "", fmt.Errorf("register instance to primary SC[%s] returned no instanceId", c.endpoint)
+	}
+	return resp.InstanceId, nil
+}
+
+func (c *SelfRegisterClient) Unregister(ctx context.Context, service *pb.MicroService, instance *pb.MicroServiceInstance) error {
+	_, err := c.doWithBody(ctx, http.MethodDelete,
+		"/v4/"+c.project+"/registry/microservices/"+service.ServiceId+"/instances/"+instance.InstanceId, nil)
+	return err
+}
+
+func (c *SelfRegisterClient) Heartbeat(ctx context.Context, service *pb.MicroService, instance *pb.MicroServiceInstance) error {
+	_, err := c.doWithBody(ctx, http.MethodPut,
+		"/v4/"+c.project+"/registry/microservices/"+service.ServiceId+"/instances/"+instance.InstanceId+"/heartbeat", nil)
+	return err
+}
+
+// doWithBody issues the request and returns the response body, so callers
+// that need the primary's assigned serviceId/instanceId (registerService,
+// registerInstance) can parse it; callers that don't just discard it.
+func (c *SelfRegisterClient) doWithBody(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("self-registration delegation to primary SC[%s] failed with status %d", c.endpoint, resp.StatusCode)
+	}
+	return respBody, nil
+}
@@ -0,0 +1,164 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v4
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/servicecomb-service-center/datasource/etcd"
+	"github.com/apache/servicecomb-service-center/pkg/log"
+	"github.com/apache/servicecomb-service-center/pkg/rest"
+	"github.com/apache/servicecomb-service-center/server/core"
+	"github.com/apache/servicecomb-service-center/server/syncer"
+)
+
+// peerDumpTimeout bounds how long dumpPeers waits on any single peer so one
+// hung/unreachable peer can't stall the whole admin/self?peers=true request;
+// FetchPeerSelfDump's http.DefaultClient has no timeout of its own.
+const peerDumpTimeout = 5 * time.Second
+
+func init() {
+	rest.RegisterServant(&SelfAdminService{})
+}
+
+// SelfAdminService exposes the local SC node's self-registration state,
+// parallel to the existing admin dump feature, so operators can tell why a
+// node disappeared from its own discovery catalog without shelling into
+// etcd.
+type SelfAdminService struct {
+}
+
+// URLPatterns registers GET /v4/{project}/admin/self.
+func (s *SelfAdminService) URLPatterns() []rest.Route {
+	return []rest.Route{
+		{Method: http.MethodGet, Path: "/v4/:project/admin/self", Func: s.DumpSelf},
+	}
+}
+
+// peerSelfDump pairs a peer SC endpoint with its self-dump (or the error
+// that kept it from answering). Self is the peer's raw admin/self JSON
+// response rather than *etcd.SelfDump: core sits below datasource/etcd, so
+// it cannot depend on that package's type to talk to a peer.
+type peerSelfDump struct {
+	Endpoint string          `json:"endpoint"`
+	Self     json.RawMessage `json:"self,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+func (s *SelfAdminService) DumpSelf(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sm := &etcd.SCManager{}
+	self, err := sm.DumpSelf(ctx)
+	if err != nil {
+		log.Error("dump self failed", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("peers") != "true" {
+		writeJSON(w, self)
+		return
+	}
+
+	peers := s.dumpPeers(ctx, projectFromPath(r.URL.Path))
+	writeJSON(w, struct {
+		Self    *etcd.SelfDump `json:"self"`
+		Peers   []peerSelfDump `json:"peers"`
+		Mirrors []mirrorView   `json:"mirrors"`
+	}{Self: self, Peers: peers, Mirrors: mirrorsView()})
+}
+
+// mirrorView is the JSON shape of a syncer.Mirrors() entry. See that
+// function's doc comment for the current in-process-only caveat: today this
+// reports only what this process has published to itself, not what a real
+// peer cluster has mirrored.
+type mirrorView struct {
+	ServiceId   string `json:"serviceId"`
+	ServiceName string `json:"serviceName"`
+	InstanceId  string `json:"instanceId"`
+	Tag         string `json:"tag"`
+}
+
+func mirrorsView() []mirrorView {
+	tasks := syncer.Mirrors()
+	views := make([]mirrorView, 0, len(tasks))
+	for _, t := range tasks {
+		views = append(views, mirrorView{
+			ServiceId:   t.Service.GetServiceId(),
+			ServiceName: t.Service.GetServiceName(),
+			InstanceId:  t.Instance.GetInstanceId(),
+			Tag:         t.Tag,
+		})
+	}
+	return views
+}
+
+// projectFromPath pulls the :project path variable out of a request matched
+// against URLPatterns' "/v4/:project/admin/self" route.
+func projectFromPath(path string) string {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+// dumpPeers fans out to every other SC instance discovered via
+// core.ServiceAPI and aggregates their self-dumps into a single cluster-wide
+// health view. Peers are queried concurrently, each bounded by
+// peerDumpTimeout, so one slow/unreachable peer doesn't stall the whole
+// request. project scopes each peer's admin/self route the same way this
+// request itself was scoped, so non-default-project deployments fan out
+// correctly instead of silently hitting the default project on every peer.
+func (s *SelfAdminService) dumpPeers(ctx context.Context, project string) []peerSelfDump {
+	endpoints, err := core.ListSelfPeers(ctx)
+	if err != nil {
+		log.Error("list self peers failed", err)
+		return nil
+	}
+	dumps := make([]peerSelfDump, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func(i int, endpoint string) {
+			defer wg.Done()
+			peerCtx, cancel := context.WithTimeout(ctx, peerDumpTimeout)
+			defer cancel()
+			dump, err := core.FetchPeerSelfDump(peerCtx, endpoint, project)
+			if err != nil {
+				dumps[i] = peerSelfDump{Endpoint: endpoint, Error: err.Error()}
+				return
+			}
+			dumps[i] = peerSelfDump{Endpoint: endpoint, Self: dump}
+		}(i, endpoint)
+	}
+	wg.Wait()
+	return dumps
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error("encode self dump response failed", err)
+	}
+}
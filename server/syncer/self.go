@@ -0,0 +1,213 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/apache/servicecomb-service-center/pkg/log"
+	pb "github.com/go-chassis/cari/discovery"
+	"github.com/go-chassis/foundation/gopool"
+)
+
+// SelfTag is the well-known tag a Task carries when it replicates an SC's own
+// self-registration to peer clusters. engine.go's syncSelf is the sole
+// producer of tasks carrying this tag.
+const SelfTag = "sc-self"
+
+// Task carries a single service/instance pair to be replicated to peer SCs,
+// optionally tagged so consumers can special-case it (see IsSelfTag below).
+type Task struct {
+	Service  *pb.MicroService
+	Instance *pb.MicroServiceInstance
+	Tag      string
+}
+
+// NewTask builds a syncer Task for the given service/instance pair.
+func NewTask(service *pb.MicroService, instance *pb.MicroServiceInstance, tag string) *Task {
+	return &Task{Service: service, Instance: instance, Tag: tag}
+}
+
+// Transport delivers self-sync tasks to the cross-cluster sync pipeline.
+// There is no real cross-cluster implementation in this tree yet: the
+// default installed at package init (mirrorStore, below) only holds entries
+// in this process's memory, so registry.self.sync_enabled only has an
+// observable effect within a single process/test today. A deployment that
+// actually needs cluster B to learn about cluster A's self-registration
+// must call SetTransport with a Transport that puts tasks on the real
+// inter-cluster channel before serving traffic; until that lands, treat
+// sync_enabled as plumbing for that future transport, not a shipped
+// cross-cluster feature.
+type Transport interface {
+	Update(ctx context.Context, task *Task) error
+	Delete(ctx context.Context, task *Task) error
+}
+
+var transport Transport
+
+// SetTransport installs the syncer's cross-cluster transport.
+func SetTransport(t Transport) {
+	transport = t
+}
+
+func init() {
+	SetTransport(newMirrorStore())
+}
+
+// Publish enqueues the task for cross-cluster replication, the same way a
+// normal microservice create/update is queued for cross-site sync.
+func Publish(ctx context.Context, task *Task) error {
+	return push(ctx, task, false)
+}
+
+// PublishDelete enqueues the removal of a previously published task.
+func PublishDelete(ctx context.Context, task *Task) error {
+	return push(ctx, task, true)
+}
+
+func push(ctx context.Context, task *Task, remove bool) error {
+	if transport == nil {
+		log.Warn(fmt.Sprintf("syncer transport not initialized, drop self-sync task[%s/%s]",
+			task.Service.GetServiceId(), task.Instance.GetInstanceId()))
+		return nil
+	}
+	if remove {
+		return transport.Delete(ctx, task)
+	}
+	return transport.Update(ctx, task)
+}
+
+// IsSelfTag reports whether a task carries the well-known "sc-self" tag.
+// Consumers use this to treat the entry as a read-only mirror of a peer
+// cluster's own SC registration: it must never be heart-beated locally and
+// must be garbage-collected once the origin cluster's lease expires.
+func IsSelfTag(tag string) bool {
+	return tag == SelfTag
+}
+
+// Filter is applied by the syncer consumer to every incoming task. Tasks
+// tagged sc-self are accepted as read-only shadows: the consumer must skip
+// local heart-beating for them and must drop the shadow once the origin
+// cluster's lease expires.
+func Filter(task *Task) (accept bool, readOnly bool) {
+	if task == nil {
+		return false, false
+	}
+	return true, IsSelfTag(task.Tag)
+}
+
+// mirrorTTL bounds how long a read-only sc-self shadow survives without a
+// refreshing Update. The origin SC calls syncSelf (and so Update) on every
+// successful self-heartbeat, so as long as the origin is alive and
+// heartbeating the mirror keeps getting pushed forward; mirrorTTL just needs
+// to comfortably exceed normal heartbeat cadence so ordinary jitter never
+// expires a mirror early. A mirror is only reaped once its origin has
+// genuinely stopped refreshing it, e.g. because it lost its lease and gave up
+// rather than re-registering.
+const mirrorTTL = 90 * time.Second
+
+// mirrorStore is the default Transport. It is a single-process placeholder,
+// not a cross-cluster one: it holds sc-self shadow entries read-only (they
+// are never scheduled for local heartbeating) in an in-memory map local to
+// this instance, and reaps any entry whose origin has stopped refreshing it.
+// It exists so Publish/PublishDelete have somewhere to go and so this
+// package is independently testable before a real transport is wired in.
+type mirrorStore struct {
+	mu      sync.Mutex
+	entries map[string]*mirrorEntry
+}
+
+type mirrorEntry struct {
+	task     *Task
+	deadline time.Time
+}
+
+func newMirrorStore() *mirrorStore {
+	s := &mirrorStore{entries: make(map[string]*mirrorEntry)}
+	gopool.Go(s.reap)
+	return s
+}
+
+func mirrorKey(task *Task) string {
+	return task.Service.GetServiceId() + "/" + task.Instance.GetInstanceId()
+}
+
+func (s *mirrorStore) Update(_ context.Context, task *Task) error {
+	accept, readOnly := Filter(task)
+	if !accept || !readOnly {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[mirrorKey(task)] = &mirrorEntry{task: task, deadline: time.Now().Add(mirrorTTL)}
+	return nil
+}
+
+func (s *mirrorStore) Delete(_ context.Context, task *Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, mirrorKey(task))
+	return nil
+}
+
+func (s *mirrorStore) reap(ctx context.Context) {
+	ticker := time.NewTicker(mirrorTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.expire()
+		}
+	}
+}
+
+func (s *mirrorStore) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for key, e := range s.entries {
+		if now.After(e.deadline) {
+			log.Warn(fmt.Sprintf("self-sync mirror[%s] expired, origin lease is gone, removing", key))
+			delete(s.entries, key)
+		}
+	}
+}
+
+// Mirrors returns a snapshot of the currently mirrored sc-self entries.
+// self_admin_controller.go's admin/self?peers=true view calls this to report
+// what this process currently holds; since mirrorStore is in-process only
+// (see its doc comment above), today that is only ever entries this same
+// process has published to itself, not entries from a real peer cluster.
+func Mirrors() []*Task {
+	s, ok := transport.(*mirrorStore)
+	if !ok {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*Task, 0, len(s.entries))
+	for _, e := range s.entries {
+		out = append(out, e.task)
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package syncer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/go-chassis/cari/discovery"
+)
+
+func selfTask(serviceID, instanceID string) *Task {
+	return NewTask(
+		&pb.MicroService{ServiceId: serviceID},
+		&pb.MicroServiceInstance{ServiceId: serviceID, InstanceId: instanceID},
+		SelfTag,
+	)
+}
+
+func TestMirrorStoreUpdateAndDelete(t *testing.T) {
+	s := &mirrorStore{entries: make(map[string]*mirrorEntry)}
+	task := selfTask("svc1", "inst1")
+
+	if err := s.Update(context.Background(), task); err != nil {
+		t.Fatalf("Update() failed: %s", err)
+	}
+	if len(s.entries) != 1 {
+		t.Fatalf("entries after Update() = %d, want 1", len(s.entries))
+	}
+
+	if err := s.Delete(context.Background(), task); err != nil {
+		t.Fatalf("Delete() failed: %s", err)
+	}
+	if len(s.entries) != 0 {
+		t.Fatalf("entries after Delete() = %d, want 0", len(s.entries))
+	}
+}
+
+func TestMirrorStoreUpdateIgnoresNonSelfTag(t *testing.T) {
+	s := &mirrorStore{entries: make(map[string]*mirrorEntry)}
+	task := NewTask(&pb.MicroService{ServiceId: "svc1"}, &pb.MicroServiceInstance{InstanceId: "inst1"}, "")
+
+	if err := s.Update(context.Background(), task); err != nil {
+		t.Fatalf("Update() failed: %s", err)
+	}
+	if len(s.entries) != 0 {
+		t.Fatalf("entries after Update() of a non-self task = %d, want 0", len(s.entries))
+	}
+}
+
+func TestMirrorStoreExpire(t *testing.T) {
+	s := &mirrorStore{entries: make(map[string]*mirrorEntry)}
+	task := selfTask("svc1", "inst1")
+
+	s.entries[mirrorKey(task)] = &mirrorEntry{task: task, deadline: time.Now().Add(-time.Second)}
+	s.expire()
+	if len(s.entries) != 0 {
+		t.Fatalf("entries after expire() of a past-deadline entry = %d, want 0", len(s.entries))
+	}
+
+	s.entries[mirrorKey(task)] = &mirrorEntry{task: task, deadline: time.Now().Add(time.Minute)}
+	s.expire()
+	if len(s.entries) != 1 {
+		t.Fatalf("entries after expire() of a not-yet-expired entry = %d, want 1", len(s.entries))
+	}
+}
+
+func TestMirrors(t *testing.T) {
+	saved := transport
+	defer SetTransport(saved)
+
+	s := &mirrorStore{entries: make(map[string]*mirrorEntry)}
+	SetTransport(s)
+
+	task := selfTask("svc1", "inst1")
+	if err := s.Update(context.Background(), task); err != nil {
+		t.Fatalf("Update() failed: %s", err)
+	}
+
+	mirrors := Mirrors()
+	if len(mirrors) != 1 || mirrors[0].Service.GetServiceId() != "svc1" {
+		t.Fatalf("Mirrors() = %v, want a single entry for svc1", mirrors)
+	}
+}